@@ -0,0 +1,142 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+// Package passphrase generates diceware-style passphrases by sampling
+// words without replacement from a wordlist, using the same crypto/rand
+// permutation primitive as the shuffle core (see internal/shuf).
+package passphrase
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"ikluft-tools/shuffle/go/internal/shuf"
+)
+
+//go:embed wordlist.txt
+var embeddedWordlist embed.FS
+
+// symbolSet is the alphabet --symbol draws a random separator-joined symbol
+// from. It excludes "-", the default --sep, so the symbol segment can't be
+// confused with the separator when splitting the output back apart.
+const symbolSet = "!@#$%^&*_=+"
+
+// Options holds the parsed command-line configuration for a Run invocation.
+type Options struct {
+	Words        int    // --words=N: number of words to sample
+	Sep          string // --sep=STR: separator joining words, default "-"
+	WordlistPath string // --wordlist=FILE: defaults to the embedded diceware-style list
+	Capitalize   bool   // --capitalize: upper-case the first letter of each word
+	Digit        bool   // --digit: inject a random digit 0-9 at a random position
+	Symbol       bool   // --symbol: inject a random symbol from symbolSet at a random position
+	ShowEntropy  bool   // --entropy: print estimated bits of entropy after the passphrase
+}
+
+// Run samples Options.Words distinct words from the wordlist and writes the
+// resulting passphrase (and optionally its entropy estimate) to out.
+func Run(opts Options, out io.Writer) error {
+	if opts.Words <= 0 {
+		return fmt.Errorf("--words must be positive")
+	}
+
+	words, err := loadWordlist(opts.WordlistPath)
+	if err != nil {
+		return err
+	}
+	if opts.Words > len(words) {
+		return fmt.Errorf("--words %d exceeds wordlist size %d", opts.Words, len(words))
+	}
+
+	rng := shuf.NewCryptoRand()
+	order := make([]int, len(words))
+	for i := range order {
+		order[i] = i
+	}
+	rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+	sep := opts.Sep
+	if sep == "" {
+		sep = "-"
+	}
+
+	chosen := make([]string, opts.Words)
+	for i := 0; i < opts.Words; i++ {
+		word := words[order[i]]
+		if opts.Capitalize {
+			word = capitalize(word)
+		}
+		chosen[i] = word
+	}
+
+	if opts.Digit {
+		chosen = insertAt(chosen, rng.Intn(len(chosen)+1), strconv.Itoa(rng.Intn(10)))
+	}
+	if opts.Symbol {
+		chosen = insertAt(chosen, rng.Intn(len(chosen)+1), string(symbolSet[rng.Intn(len(symbolSet))]))
+	}
+	fmt.Fprintln(out, strings.Join(chosen, sep))
+
+	if opts.ShowEntropy {
+		bits := float64(opts.Words) * math.Log2(float64(len(words)))
+		fmt.Fprintf(out, "entropy: %.2f bits\n", bits)
+	}
+	return nil
+}
+
+// loadWordlist reads one word per line from path, or from the embedded
+// default wordlist when path is empty.
+func loadWordlist(path string) ([]string, error) {
+	var reader io.Reader
+	if path == "" {
+		f, err := embeddedWordlist.Open("wordlist.txt")
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	var words []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// insertAt returns segments with extra inserted at index pos.
+func insertAt(segments []string, pos int, extra string) []string {
+	out := make([]string, 0, len(segments)+1)
+	out = append(out, segments[:pos]...)
+	out = append(out, extra)
+	out = append(out, segments[pos:]...)
+	return out
+}
+
+// capitalize upper-cases the first rune of word.
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
@@ -0,0 +1,89 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package passphrase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunDefaultWordlist(t *testing.T) {
+	opts := Options{Words: 4, Sep: "-"}
+	var out bytes.Buffer
+	if err := Run(opts, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	words := strings.Split(strings.TrimSpace(out.String()), "-")
+	if len(words) != 4 {
+		t.Errorf("expected 4 words, got %d: %v", len(words), words)
+	}
+	seen := map[string]bool{}
+	for _, w := range words {
+		if seen[w] {
+			t.Errorf("word %q sampled more than once", w)
+		}
+		seen[w] = true
+	}
+}
+
+func TestRunCapitalize(t *testing.T) {
+	opts := Options{Words: 3, Sep: "-", Capitalize: true}
+	var out bytes.Buffer
+	if err := Run(opts, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	for _, w := range strings.Split(strings.TrimSpace(out.String()), "-") {
+		if w[:1] != strings.ToUpper(w[:1]) {
+			t.Errorf("word %q not capitalized", w)
+		}
+	}
+}
+
+func TestRunEntropy(t *testing.T) {
+	opts := Options{Words: 2, Sep: "-", ShowEntropy: true}
+	var out bytes.Buffer
+	if err := Run(opts, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "entropy:") {
+		t.Errorf("expected entropy line in output, got %q", out.String())
+	}
+}
+
+func TestRunDigitAndSymbol(t *testing.T) {
+	opts := Options{Words: 3, Sep: "-", Digit: true, Symbol: true}
+	var out bytes.Buffer
+	if err := Run(opts, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	segments := strings.Split(strings.TrimSpace(out.String()), "-")
+	if len(segments) != 5 {
+		t.Fatalf("expected 3 words + digit + symbol = 5 segments, got %d: %v", len(segments), segments)
+	}
+
+	var sawDigit, sawSymbol bool
+	for _, seg := range segments {
+		switch {
+		case len(seg) == 1 && seg[0] >= '0' && seg[0] <= '9':
+			sawDigit = true
+		case len(seg) == 1 && strings.ContainsRune(symbolSet, rune(seg[0])):
+			sawSymbol = true
+		}
+	}
+	if !sawDigit {
+		t.Errorf("expected a digit segment in %v", segments)
+	}
+	if !sawSymbol {
+		t.Errorf("expected a symbol segment in %v", segments)
+	}
+}
+
+func TestRunWordsExceedsWordlist(t *testing.T) {
+	opts := Options{Words: 1 << 20, Sep: "-"}
+	var out bytes.Buffer
+	if err := Run(opts, &out); err == nil {
+		t.Errorf("expected error when --words exceeds wordlist size, got nil")
+	}
+}
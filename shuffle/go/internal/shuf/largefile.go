@@ -0,0 +1,160 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+)
+
+// indexEntry records the byte offset and length of one line (or fixed-width
+// record) in the input file, without ever holding the line's content.
+type indexEntry struct {
+	Off int64
+	Len int64
+}
+
+// runLarge shuffles a file by permuting a compact offset index instead of
+// loading every line into memory, so memory use for multi-gigabyte inputs
+// grows with the number of lines (one small index entry each) rather than
+// with their content. It falls back to the in-memory path when the input
+// isn't a seekable regular file (e.g. stdin, a pipe); with --record-size,
+// such input is an error instead.
+func runLarge(opts Options, in io.Reader, out io.Writer, rng *rand.Rand) error {
+	if len(opts.Args) == 0 || opts.Args[0] == "-" {
+		if opts.HasRecordSize {
+			return fmt.Errorf("--record-size requires a seekable file argument")
+		}
+		return runLargeFallback(opts, in, out, rng)
+	}
+
+	f, err := os.Open(opts.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		if opts.HasRecordSize {
+			return fmt.Errorf("--record-size requires a seekable file argument")
+		}
+		return runLargeFallback(opts, f, out, rng)
+	}
+
+	var entries []indexEntry
+	if opts.HasRecordSize {
+		entries, err = fixedRecordIndex(fi.Size(), opts.RecordSize)
+	} else {
+		entries, err = buildLineIndex(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if opts.Repeat {
+		if len(entries) == 0 {
+			return fmt.Errorf("no input lines to sample from")
+		}
+		n := int64(len(entries))
+		if opts.HasCount {
+			n = int64(opts.Count)
+		}
+		for i := int64(0); i < n; i++ {
+			if err := writeEntry(w, f, entries[rng.Intn(len(entries))]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+	n := len(entries)
+	if opts.HasCount && opts.Count < n {
+		n = opts.Count
+	}
+	for i := 0; i < n; i++ {
+		if err := writeEntry(w, f, entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runLargeFallback shuffles in memory when the offset-indexed path isn't
+// available for this input.
+func runLargeFallback(opts Options, in io.Reader, out io.Writer, rng *rand.Rand) error {
+	lines, err := readLines(opts, in, rng)
+	if err != nil {
+		return err
+	}
+	return emit(lines, opts, out, rng)
+}
+
+// fixedRecordIndex computes offsets for fixed-width records as i*recordSize,
+// skipping the index-building pass entirely.
+func fixedRecordIndex(fileSize, recordSize int64) ([]indexEntry, error) {
+	if recordSize <= 0 {
+		return nil, fmt.Errorf("--record-size must be positive, got %d", recordSize)
+	}
+	if fileSize%recordSize != 0 {
+		return nil, fmt.Errorf("file size %d is not a multiple of --record-size %d", fileSize, recordSize)
+	}
+	count := fileSize / recordSize
+	entries := make([]indexEntry, count)
+	for i := range entries {
+		entries[i] = indexEntry{Off: int64(i) * recordSize, Len: recordSize}
+	}
+	return entries, nil
+}
+
+// buildLineIndex makes a single sequential pass over f recording the
+// (offset, length) of each line, excluding the trailing newline. A final
+// line with no trailing newline is still indexed correctly.
+func buildLineIndex(f *os.File) ([]indexEntry, error) {
+	reader := bufio.NewReader(f)
+	var entries []indexEntry
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		n := int64(len(line))
+		if n > 0 {
+			length := n
+			if line[n-1] == '\n' {
+				length--
+			}
+			entries = append(entries, indexEntry{Off: offset, Len: length})
+			offset += n
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// writeEntry reads one indexed line from f via ReadAt and writes it to w
+// followed by a newline.
+func writeEntry(w *bufio.Writer, f *os.File, e indexEntry) error {
+	buf := make([]byte, e.Len)
+	if _, err := f.ReadAt(buf, e.Off); err != nil && err != io.EOF {
+		return err
+	}
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
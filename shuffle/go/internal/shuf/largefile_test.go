@@ -0,0 +1,72 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRunLargeLineIndex(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/input.txt"
+	if err := os.WriteFile(path, []byte("1\n2\n3\n4\n5"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	opts := Options{Large: true, Args: []string{path}}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "1,2,3,4,5" {
+		t.Errorf("expected permutation of 1..5; got %v", got)
+	}
+}
+
+func TestRunLargeRecordSize(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/records.bin"
+	if err := os.WriteFile(path, []byte("aaabbbccc"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	opts := Options{RecordSize: 3, HasRecordSize: true, Args: []string{path}}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "aaa,bbb,ccc" {
+		t.Errorf("expected permutation of aaa,bbb,ccc; got %v", got)
+	}
+}
+
+func TestRunLargeRecordSizeRequiresSeekableFile(t *testing.T) {
+	opts := Options{RecordSize: 3, HasRecordSize: true}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader("aaabbbccc"), &out); err == nil {
+		t.Errorf("expected error when --record-size is used with non-seekable input, got nil")
+	}
+}
+
+func TestRunLargeRecordSizeNotMultiple(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/records.bin"
+	if err := os.WriteFile(path, []byte("aaabb"), 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	opts := Options{RecordSize: 3, HasRecordSize: true, Args: []string{path}}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err == nil {
+		t.Errorf("expected error for non-multiple file size, got nil")
+	}
+}
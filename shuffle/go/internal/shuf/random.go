@@ -0,0 +1,73 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+
+	croand "crypto/rand"
+	mathrand "math/rand"
+)
+
+// readerSource adapts an io.Reader into a math/rand.Source64, consuming 8
+// bytes per Uint64 call. It backs both the default CSPRNG (reading from
+// crypto/rand.Reader) and --random-source=FILE (reading from an arbitrary
+// file such as /dev/urandom or a fixed file for reproducible test runs).
+//
+// math/rand's Source interface has no way to return an error, and several
+// of its algorithms (e.g. Shuffle's modulo-bias rejection sampling) loop
+// until they see a value they consider acceptable. A reader that silently
+// returned a zero value on a short read could make that loop spin forever,
+// so a short read instead panics with shortReadPanic; Run recovers it and
+// reports it as a normal error.
+type readerSource struct {
+	r io.Reader
+}
+
+type shortReadPanic struct{ err error }
+
+func (s *readerSource) Uint64() uint64 {
+	var buf [8]byte
+	if _, err := io.ReadFull(s.r, buf[:]); err != nil {
+		panic(shortReadPanic{err})
+	}
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+func (s *readerSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed is a no-op: a reader-backed source draws all of its randomness from
+// the underlying reader and cannot be reseeded.
+func (s *readerSource) Seed(int64) {}
+
+// NewCryptoRand returns a *rand.Rand backed directly by crypto/rand, the
+// same CSPRNG Run uses by default. It is exported for other commands built
+// on this package (e.g. passphrase) that need the same randomness source
+// without going through Options.
+func NewCryptoRand() *mathrand.Rand {
+	return mathrand.New(&readerSource{r: croand.Reader})
+}
+
+// newRand builds the *rand.Rand selected by opts: a file-backed source when
+// --random-source is given, a deterministic math/rand source when --seed is
+// given, or a crypto/rand-backed CSPRNG by default. The returned io.Closer
+// is nil when there is nothing to close.
+func newRand(opts Options) (*mathrand.Rand, io.Closer, error) {
+	switch {
+	case opts.RandomSource != "":
+		f, err := os.Open(opts.RandomSource)
+		if err != nil {
+			return nil, nil, err
+		}
+		return mathrand.New(&readerSource{r: f}), f, nil
+	case opts.HasSeed:
+		return mathrand.New(mathrand.NewSource(opts.Seed)), nil, nil
+	default:
+		return NewCryptoRand(), nil, nil
+	}
+}
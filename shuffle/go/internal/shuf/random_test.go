@@ -0,0 +1,42 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunWithSeedIsDeterministic(t *testing.T) {
+	opts := Options{Seed: 42, HasSeed: true}
+	in := "1\n2\n3\n4\n5\n"
+
+	var out1, out2 bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out1); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if err := Run(opts, strings.NewReader(in), &out2); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if out1.String() != out2.String() {
+		t.Errorf("same seed produced different output:\n%q\n%q", out1.String(), out2.String())
+	}
+}
+
+func TestRunWithRandomSourceShortRead(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/entropy"
+	if err := os.WriteFile(path, []byte{0x01, 0x02, 0x03}, 0o600); err != nil {
+		t.Fatalf("os.WriteFile: %v", err)
+	}
+
+	opts := Options{RandomSource: path}
+	in := "1\n2\n3\n4\n5\n"
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out); err == nil {
+		t.Errorf("expected error on short random-source read, got nil")
+	}
+}
@@ -0,0 +1,318 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+// Package shuf implements the line-shuffling engine behind the shuffle
+// command. It is split out from main so the various modes (echo, integer
+// range, file input, counted/reservoir sampling) can be unit-tested without
+// going through the command-line flag parser.
+package shuf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Options holds the parsed command-line configuration for a Run invocation.
+type Options struct {
+	Echo          bool     // -e/--echo: treat Args as literal input lines
+	Args          []string // remaining non-flag args: echo lines, or the input file path
+	Range         string   // -i LO-HI: permute an integer range instead of reading lines
+	Count         int      // -n COUNT: value of Count, only meaningful when HasCount is true
+	HasCount      bool     // true if -n was given
+	Output        string   // -o FILE: write output here instead of stdout
+	Repeat        bool     // -r: sample with repetition
+	RandomSource  string   // --random-source=FILE: read raw randomness from this file instead of crypto/rand
+	Seed          int64    // --seed=N: value of Seed, only meaningful when HasSeed is true
+	HasSeed       bool     // true if --seed was given
+	Large         bool     // --large: force offset-indexed shuffling
+	RecordSize    int64    // --record-size=N: value of RecordSize, only meaningful when HasRecordSize is true
+	HasRecordSize bool     // true if --record-size was given
+	Weighted      bool     // --weighted: interpret each line as WEIGHT<sep>TEXT and sample by weight
+	WeightSep     string   // --weight-sep=STR: separator between weight and text, default "\t"
+}
+
+// autoLargeThreshold is the input file size above which Run automatically
+// switches to the offset-indexed path even without --large.
+const autoLargeThreshold = 64 * 1024 * 1024 // 64MiB
+
+// useLargePath reports whether opts and the input named by opts.Args[0]
+// call for the offset-indexed large-file path instead of loading every
+// line into memory.
+func useLargePath(opts Options) bool {
+	if opts.Echo || opts.Range != "" {
+		return false
+	}
+	if opts.Large || opts.HasRecordSize {
+		return true
+	}
+	if len(opts.Args) == 0 || opts.Args[0] == "-" {
+		return false
+	}
+	fi, err := os.Stat(opts.Args[0])
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+	return fi.Size() > autoLargeThreshold
+}
+
+// Run executes opts, reading input lines from in (used for stdin / "-")
+// and writing the shuffled output to out (used when Options.Output is empty).
+func Run(opts Options, in io.Reader, out io.Writer) (err error) {
+	if opts.HasCount && opts.Count < 0 {
+		return fmt.Errorf("-n must not be negative, got %d", opts.Count)
+	}
+
+	rng, closer, err := newRand(opts)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			sp, ok := r.(shortReadPanic)
+			if !ok {
+				panic(r)
+			}
+			err = sp.err
+		}
+	}()
+
+	writer := out
+	if opts.Output != "" {
+		outfile, err := os.Create(opts.Output)
+		if err != nil {
+			return err
+		}
+		defer outfile.Close()
+		writer = outfile
+	}
+
+	switch {
+	case opts.Range != "":
+		return runRange(opts, writer, rng)
+	case opts.Weighted:
+		return runWeighted(opts, in, writer, rng)
+	case useLargePath(opts):
+		return runLarge(opts, in, writer, rng)
+	default:
+		lines, err := readLines(opts, in, rng)
+		if err != nil {
+			return err
+		}
+		return emit(lines, opts, writer, rng)
+	}
+}
+
+// openInput opens the file named by opts.Args[0], or falls back to in when
+// no file is given or it is "-" (stdin). The returned closer is nil when
+// there is nothing to close.
+func openInput(opts Options, in io.Reader) (io.Reader, io.Closer, error) {
+	if len(opts.Args) == 0 || opts.Args[0] == "-" {
+		return in, nil, nil
+	}
+	infile, err := os.Open(opts.Args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return infile, infile, nil
+}
+
+// readLines gathers the input lines according to opts: either the literal
+// echo arguments, or the contents of a file (or stdin when no file, or "-",
+// is given).
+func readLines(opts Options, in io.Reader, rng *rand.Rand) ([]string, error) {
+	if opts.Echo {
+		return opts.Args, nil
+	}
+
+	if opts.HasCount && !opts.Repeat {
+		return reservoirSample(opts, in, rng)
+	}
+
+	reader, closer, err := openInput(opts, in)
+	if err != nil {
+		return nil, err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// reservoirSample streams the input one line at a time and keeps a uniform
+// random sample of opts.Count lines (Algorithm R), so the full input never
+// needs to be held in memory.
+func reservoirSample(opts Options, in io.Reader, rng *rand.Rand) ([]string, error) {
+	reader, closer, err := openInput(opts, in)
+	if err != nil {
+		return nil, err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+
+	reservoir := make([]string, 0, opts.Count)
+	scanner := bufio.NewScanner(reader)
+	seen := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		seen++
+		if len(reservoir) < opts.Count {
+			reservoir = append(reservoir, line)
+			continue
+		}
+		j := rng.Intn(seen)
+		if j < opts.Count {
+			reservoir[j] = line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	rng.Shuffle(len(reservoir), func(i, j int) { reservoir[i], reservoir[j] = reservoir[j], reservoir[i] })
+	return reservoir, nil
+}
+
+// runRange permutes the integer range given by opts.Range ("LO-HI") without
+// materializing every number as a string up front: it shuffles a slice of
+// int64 offsets and only formats each one at emission time.
+func runRange(opts Options, out io.Writer, rng *rand.Rand) error {
+	lo, hi, err := parseRange(opts.Range)
+	if err != nil {
+		return err
+	}
+	count := hi - lo + 1
+
+	if opts.Repeat {
+		n := count
+		if opts.HasCount {
+			n = int64(opts.Count)
+		}
+		w := bufio.NewWriter(out)
+		defer w.Flush()
+		for i := int64(0); i < n; i++ {
+			fmt.Fprintln(w, lo+rng.Int63n(count))
+		}
+		return nil
+	}
+
+	n := count
+	if opts.HasCount && int64(opts.Count) < n {
+		n = int64(opts.Count)
+	}
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if opts.HasCount {
+		// Partial Fisher-Yates over a sparse swap map: only the n
+		// positions actually drawn are ever recorded, so selecting a
+		// handful of values out of a huge range stays O(n) instead of
+		// allocating the full count-sized offset slice.
+		for _, v := range partialShuffleIndices(count, n, rng) {
+			fmt.Fprintln(w, lo+v)
+		}
+		return nil
+	}
+
+	offsets := make([]int64, count)
+	for i := range offsets {
+		offsets[i] = int64(i)
+	}
+	rng.Shuffle(len(offsets), func(i, j int) { offsets[i], offsets[j] = offsets[j], offsets[i] })
+	for i := int64(0); i < n; i++ {
+		fmt.Fprintln(w, lo+offsets[i])
+	}
+	return nil
+}
+
+// partialShuffleIndices draws the first n elements of a Fisher-Yates shuffle
+// of [0,size) without materializing the full size-length array: it tracks
+// only the sparse set of positions that have actually been swapped, in a
+// map that never grows past n entries.
+func partialShuffleIndices(size, n int64, rng *rand.Rand) []int64 {
+	swapped := make(map[int64]int64, n)
+	at := func(i int64) int64 {
+		if v, ok := swapped[i]; ok {
+			return v
+		}
+		return i
+	}
+
+	result := make([]int64, n)
+	for i := int64(0); i < n; i++ {
+		j := i + rng.Int63n(size-i)
+		result[i] = at(j)
+		swapped[j] = at(i)
+	}
+	return result
+}
+
+// rangeSpec matches the "-i LO-HI" flag value, where LO and HI may each
+// carry their own leading minus sign (GNU shuf allows negative bounds,
+// e.g. "-i -5-5" or "-i -10--1").
+var rangeSpec = regexp.MustCompile(`^(-?[0-9]+)-(-?[0-9]+)$`)
+
+// parseRange parses the "-i LO-HI" flag value.
+func parseRange(spec string) (lo, hi int64, err error) {
+	m := rangeSpec.FindStringSubmatch(spec)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid range %q: expected LO-HI", spec)
+	}
+	lo, err = strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", spec, err)
+	}
+	hi, err = strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", spec, err)
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("invalid range %q: hi < lo", spec)
+	}
+	return lo, hi, nil
+}
+
+// emit shuffles (or samples with repetition from) lines and writes the
+// result to out.
+func emit(lines []string, opts Options, out io.Writer, rng *rand.Rand) error {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	if opts.Repeat {
+		if len(lines) == 0 {
+			return fmt.Errorf("no input lines to sample from")
+		}
+		n := len(lines)
+		if opts.HasCount {
+			n = opts.Count
+		}
+		for i := 0; i < n; i++ {
+			fmt.Fprintln(w, lines[rng.Intn(len(lines))])
+		}
+		return nil
+	}
+
+	rng.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	n := len(lines)
+	if opts.HasCount && opts.Count < n {
+		n = opts.Count
+	}
+	for i := 0; i < n; i++ {
+		fmt.Fprintln(w, lines[i])
+	}
+	return nil
+}
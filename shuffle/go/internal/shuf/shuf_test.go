@@ -0,0 +1,136 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRunEcho(t *testing.T) {
+	opts := Options{Echo: true, Args: []string{"a", "b", "c"}}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("expected permutation of a,b,c; got %v", got)
+	}
+}
+
+func TestRunFileInput(t *testing.T) {
+	opts := Options{}
+	in := strings.NewReader("1\n2\n3\n4\n5\n")
+	var out bytes.Buffer
+	if err := Run(opts, in, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "1,2,3,4,5" {
+		t.Errorf("expected permutation of 1..5; got %v", got)
+	}
+}
+
+func TestRunCount(t *testing.T) {
+	opts := Options{Count: 2, HasCount: true}
+	in := strings.NewReader("1\n2\n3\n4\n5\n")
+	var out bytes.Buffer
+	if err := Run(opts, in, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	if len(got) != 2 {
+		t.Errorf("expected 2 lines, got %d: %v", len(got), got)
+	}
+}
+
+func TestRunRange(t *testing.T) {
+	opts := Options{Range: "1-5"}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "1,2,3,4,5" {
+		t.Errorf("expected permutation of 1..5; got %v", got)
+	}
+}
+
+func TestRunRangeNegativeBounds(t *testing.T) {
+	opts := Options{Range: "-5--1"}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "-1,-2,-3,-4,-5" {
+		t.Errorf("expected permutation of -5..-1; got %v", got)
+	}
+}
+
+func TestRunRangeStraddlingZero(t *testing.T) {
+	opts := Options{Range: "-2-2"}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "-1,-2,0,1,2" {
+		t.Errorf("expected permutation of -2..2; got %v", got)
+	}
+}
+
+func TestRunNegativeCount(t *testing.T) {
+	opts := Options{Count: -5, HasCount: true}
+	in := strings.NewReader("1\n2\n3\n")
+	var out bytes.Buffer
+	if err := Run(opts, in, &out); err == nil {
+		t.Errorf("expected error for negative -n, got nil")
+	}
+}
+
+func TestRunRangeCountPartialShuffle(t *testing.T) {
+	opts := Options{Range: "1-1000000", Count: 5, HasCount: true}
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	if len(got) != 5 {
+		t.Fatalf("expected 5 lines, got %d: %v", len(got), got)
+	}
+	seen := map[string]bool{}
+	for _, line := range got {
+		if seen[line] {
+			t.Errorf("value %q sampled more than once", line)
+		}
+		seen[line] = true
+	}
+}
+
+func TestRunRepeat(t *testing.T) {
+	opts := Options{Repeat: true, Count: 10, HasCount: true}
+	in := strings.NewReader("x\ny\n")
+	var out bytes.Buffer
+	if err := Run(opts, in, &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	if len(got) != 10 {
+		t.Errorf("expected 10 lines, got %d", len(got))
+	}
+	for _, line := range got {
+		if line != "x" && line != "y" {
+			t.Errorf("unexpected line %q", line)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// weightedLine is one WEIGHT<sep>TEXT input line together with its A-Res
+// sampling key u^(1/weight).
+type weightedLine struct {
+	key  float64
+	text string
+}
+
+// runWeighted implements A-Res weighted random sampling: each line is
+// "WEIGHT<sep>TEXT", and lines are emitted in descending order of the key
+// u^(1/weight), which yields a random permutation where each position is
+// filled by one of the not-yet-emitted lines with probability proportional
+// to its weight. When
+// opts.HasCount is set, only the top opts.Count lines are kept, tracked in a
+// min-heap for O(N log k) streaming top-k sampling.
+func runWeighted(opts Options, in io.Reader, out io.Writer, rng *rand.Rand) error {
+	sep := opts.WeightSep
+	if sep == "" {
+		sep = "\t"
+	}
+
+	reader, closer, err := openInput(opts, in)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+
+	var all []weightedLine
+	var top weightedHeap
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line, err := parseWeightedLine(scanner.Text(), sep, rng)
+		if err != nil {
+			return err
+		}
+		if !opts.HasCount {
+			all = append(all, line)
+			continue
+		}
+		if len(top) < opts.Count {
+			heap.Push(&top, line)
+			continue
+		}
+		if len(top) > 0 && line.key > top[0].key {
+			heap.Pop(&top)
+			heap.Push(&top, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	result := all
+	if opts.HasCount {
+		result = []weightedLine(top)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].key > result[j].key })
+
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+	for _, line := range result {
+		fmt.Fprintln(w, line.text)
+	}
+	return nil
+}
+
+// parseWeightedLine splits raw on sep into a WEIGHT and TEXT and computes
+// its A-Res key.
+func parseWeightedLine(raw, sep string, rng *rand.Rand) (weightedLine, error) {
+	parts := strings.SplitN(raw, sep, 2)
+	if len(parts) != 2 {
+		return weightedLine{}, fmt.Errorf("weighted line missing %q separator: %q", sep, raw)
+	}
+	weight, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || weight <= 0 {
+		return weightedLine{}, fmt.Errorf("invalid weight %q in line %q", parts[0], raw)
+	}
+
+	u := rng.Float64()
+	for u == 0 {
+		u = rng.Float64()
+	}
+	return weightedLine{key: math.Pow(u, 1/weight), text: parts[1]}, nil
+}
+
+// weightedHeap is a min-heap of weightedLine ordered by key, used to track
+// the top opts.Count keys seen so far without storing every input line.
+type weightedHeap []weightedLine
+
+func (h weightedHeap) Len() int            { return len(h) }
+func (h weightedHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h weightedHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *weightedHeap) Push(x interface{}) { *h = append(*h, x.(weightedLine)) }
+func (h *weightedHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,61 @@
+// Open Source licensing under terms of GNU General Public License version 3
+// SPDX identifier: GPL-3.0-only
+
+package shuf
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRunWeightedFullPermutation(t *testing.T) {
+	opts := Options{Weighted: true}
+	in := "1\ta\n1\tb\n1\tc\n1\td\n"
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "a,b,c,d" {
+		t.Errorf("expected permutation of a,b,c,d; got %v", got)
+	}
+}
+
+func TestRunWeightedTopK(t *testing.T) {
+	opts := Options{Weighted: true, Count: 2, HasCount: true}
+	in := "1\ta\n1\tb\n1\tc\n1\td\n1\te\n"
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	if len(got) != 2 {
+		t.Errorf("expected 2 lines, got %d: %v", len(got), got)
+	}
+}
+
+func TestRunWeightedCustomSep(t *testing.T) {
+	opts := Options{Weighted: true, WeightSep: ","}
+	in := "1,a\n1,b\n"
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	got := strings.Fields(out.String())
+	sort.Strings(got)
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("expected permutation of a,b; got %v", got)
+	}
+}
+
+func TestRunWeightedInvalidWeight(t *testing.T) {
+	opts := Options{Weighted: true}
+	in := "notanumber\ta\n"
+	var out bytes.Buffer
+	if err := Run(opts, strings.NewReader(in), &out); err == nil {
+		t.Errorf("expected error for invalid weight, got nil")
+	}
+}
@@ -6,64 +6,97 @@
 // Open Source licensing under terms of GNU General Public License version 3
 // SPDX identifier: GPL-3.0-only
 // https://opensource.org/licenses/GPL-3.0
-// https://www.gnu.org/licenses/gpl-3.0.en.html
 //
-// usage: shuffle input.txt > output.txt
+// usage: shuffle [-e] [-i LO-HI] [-n COUNT] [-o FILE] [-r] [--random-source FILE]
+//                [--seed N] [--large] [--record-size N] [FILE]
+//        shuffle passphrase [--words N] [--sep STR] [--wordlist FILE] [--capitalize]
+//                           [--digit] [--symbol] [--entropy]
+// a drop-in subset of GNU coreutils shuf(1), plus a passphrase subcommand
+// built on the same sampling core
 
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"math/rand"
 	"os"
-	"time"
-)
 
-// read file line-by-line to a list
-func readFileLines(infilePath string) ([]string, error) {
-	infile, err := os.Open(infilePath)
-	if err != nil {
-		return nil, err
-	}
-	defer infile.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(infile)
-	for scanner.Scan() {
-		var line string
-		line = scanner.Text()
-		lines = append(lines, line)
-	}
-	return lines, scanner.Err()
-}
+	"ikluft-tools/shuffle/go/internal/passphrase"
+	"ikluft-tools/shuffle/go/internal/shuf"
+)
 
 // mainline - program starts here
 func main() {
-	// read file named by command-line argument
-	if len(os.Args) < 1 {
-		panic("specifiy file path on command line")
-		os.Exit(1)
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "passphrase" {
+		err = runPassphrase(os.Args[2:])
+	} else {
+		err = runShuffle(os.Args[1:])
 	}
-	filePath := os.Args[1]
-	lines, err := readFileLines(filePath)
-
-	// check for errors
 	if err != nil {
-		fmt.Println(err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	if lines == nil {
-		fmt.Println("read error")
-		os.Exit(1)
+}
+
+// runShuffle parses shuf(1)-style flags from args and runs the shuffle core.
+func runShuffle(args []string) error {
+	fs := flag.NewFlagSet("shuffle", flag.ExitOnError)
+	opts := shuf.Options{}
+	var count int
+	var seed int64
+	var recordSize int64
+
+	fs.BoolVar(&opts.Echo, "e", false, "treat each remaining argument as an input line (alias: --echo)")
+	fs.BoolVar(&opts.Echo, "echo", false, "treat each remaining argument as an input line")
+	fs.StringVar(&opts.Range, "i", "", "permute the integer range LO-HI instead of reading lines")
+	fs.IntVar(&count, "n", 0, "output at most COUNT lines")
+	fs.StringVar(&opts.Output, "o", "", "write output to FILE instead of stdout")
+	fs.BoolVar(&opts.Repeat, "r", false, "sample with repetition")
+	fs.StringVar(&opts.RandomSource, "random-source", "", "read randomness from FILE instead of crypto/rand")
+	fs.Int64Var(&seed, "seed", 0, "seed the PRNG with N for a deterministic, reproducible shuffle")
+	fs.BoolVar(&opts.Large, "large", false, "force offset-indexed shuffling for large files")
+	fs.Int64Var(&recordSize, "record-size", 0, "treat input as fixed-width N-byte records and skip index building")
+	fs.BoolVar(&opts.Weighted, "weighted", false, "interpret each line as WEIGHT<sep>TEXT and sample by weight")
+	fs.StringVar(&opts.WeightSep, "weight-sep", "\t", "separator between weight and text in --weighted mode")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
 
-	// shuffle lines
-	rand.Seed(time.Now().UnixNano()) // seed random number generator using nanoseconds since Unix epoch (1970)
-	rand.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	opts.Args = fs.Args()
+	opts.Count = count
+	opts.Seed = seed
+	opts.RecordSize = recordSize
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "n":
+			opts.HasCount = true
+		case "seed":
+			opts.HasSeed = true
+		case "record-size":
+			opts.HasRecordSize = true
+		}
+	})
 
-	// output
-	for _, line := range lines {
-		fmt.Println(line)
+	return shuf.Run(opts, os.Stdin, os.Stdout)
+}
+
+// runPassphrase parses passphrase-subcommand flags from args and runs the
+// passphrase generator.
+func runPassphrase(args []string) error {
+	fs := flag.NewFlagSet("passphrase", flag.ExitOnError)
+	opts := passphrase.Options{}
+
+	fs.IntVar(&opts.Words, "words", 6, "number of words to sample from the wordlist")
+	fs.StringVar(&opts.Sep, "sep", "-", "separator joining the sampled words")
+	fs.StringVar(&opts.WordlistPath, "wordlist", "", "wordlist file to sample from (default: embedded list)")
+	fs.BoolVar(&opts.Capitalize, "capitalize", false, "capitalize the first letter of each word")
+	fs.BoolVar(&opts.Digit, "digit", false, "inject a random digit at a random position")
+	fs.BoolVar(&opts.Symbol, "symbol", false, "inject a random symbol at a random position")
+	fs.BoolVar(&opts.ShowEntropy, "entropy", false, "print the estimated bits of entropy after the passphrase")
+	if err := fs.Parse(args); err != nil {
+		return err
 	}
+
+	return passphrase.Run(opts, os.Stdout)
 }